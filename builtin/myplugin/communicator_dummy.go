@@ -1,24 +1,77 @@
 package myplugin
 
 import (
+	"context"
+	"strings"
+
 	"github.com/hashicorp/vagrant-plugin-sdk/component"
 	plugincore "github.com/hashicorp/vagrant-plugin-sdk/core"
 )
 
-type DummyConfig struct {
+// CommunicatorConfig is the set of connection options shared by the
+// communicators in myplugin. Not every communicator uses every field;
+// DummyCommunicator only uses SecretPath and SecretField, to resolve
+// Password in Init, while WinRMCommunicator requires Host and uses the
+// rest to build its WinRM endpoint.
+type CommunicatorConfig struct {
+	Host     string
+	Port     int
+	User     string
+	Password string
+
+	// Transport selects the WinRM authentication scheme: "basic",
+	// "ntlm", or "kerberos". Defaults to "basic" when empty.
+	Transport string
+
+	// UseHTTPS selects the WinRM HTTPS endpoint instead of plain HTTP,
+	// and determines Port's default (5986 vs 5985). Insecure and CACert
+	// only take effect when this is set; there would otherwise be no way
+	// to ask for HTTPS with certificate verification skipped on the
+	// default port.
+	UseHTTPS bool
+
+	// Insecure skips TLS certificate verification when connecting over
+	// HTTPS. It has no effect unless UseHTTPS is set.
+	Insecure bool
+
+	// CACert is the PEM-encoded CA certificate used to verify the
+	// guest's WinRM HTTPS endpoint. Ignored when Insecure is set, or
+	// when UseHTTPS is not.
+	CACert string
+
+	// SecretPath and SecretField, when both set, resolve Password from
+	// Vault, through the shared VaultResolver, instead of reading it from
+	// the Vagrantfile. The resolved value is materialized in memory
+	// only; it is never written back to the Vagrantfile or config.
+	SecretPath  string
+	SecretField string
 }
 
 // DummyCommunicator is a Communicator implementation for myplugin.
 type DummyCommunicator struct {
-	config DummyConfig
+	config CommunicatorConfig
 }
 
 func (h *DummyCommunicator) MatchFunc() interface{} {
 	return h.Match
 }
 
+// Match claims machine as the catch-all fallback: it matches whenever no
+// communicator name is configured, or the machine can't report one at
+// all. It never outranks a communicator that matches machine's explicit
+// configuration, since CommunicatorPriority tries "dummy" last.
 func (h *DummyCommunicator) Match(machine plugincore.Machine) (isMatch bool, err error) {
-	return true, nil
+	named, ok := machine.(machineCommunicatorName)
+	if !ok {
+		return true, nil
+	}
+
+	name, err := named.CommunicatorName()
+	if err != nil {
+		return false, err
+	}
+
+	return name == "" || strings.EqualFold(name, "dummy"), nil
 }
 
 func (h *DummyCommunicator) InitFunc() interface{} {
@@ -26,6 +79,12 @@ func (h *DummyCommunicator) InitFunc() interface{} {
 }
 
 func (h *DummyCommunicator) Init(machine plugincore.Machine) error {
+	password, err := resolveSecret(context.Background(), h.config)
+	if err != nil {
+		return err
+	}
+	h.config.Password = password
+
 	return nil
 }
 
@@ -51,7 +110,8 @@ func (h *DummyCommunicator) DownloadFunc() interface{} {
 
 func (h *DummyCommunicator) Download(
 	machine plugincore.Machine,
-	source, destination string,
+	entries []TransferEntry,
+	options ...CommunicatorOptions,
 ) error {
 	return nil
 }
@@ -62,7 +122,8 @@ func (h *DummyCommunicator) UploadFunc() interface{} {
 
 func (h *DummyCommunicator) Upload(
 	machine plugincore.Machine,
-	source, destination string,
+	entries []TransferEntry,
+	options ...CommunicatorOptions,
 ) error {
 	return nil
 }
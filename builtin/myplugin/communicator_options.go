@@ -0,0 +1,79 @@
+package myplugin
+
+import (
+	"context"
+	"io"
+	"time"
+)
+
+// CommunicatorOptions customizes a single Execute, PrivilegedExecute,
+// Test, Upload, or Download call without altering the communicator's
+// persistent configuration.
+type CommunicatorOptions struct {
+	// Context bounds the call's lifetime. Cancelling it (e.g. on Ctrl-C)
+	// interrupts the in-flight command and causes the call to return
+	// early with ctx.Err(). A nil Context is treated as
+	// context.Background().
+	Context context.Context
+
+	// Stdout and Stderr, when set, receive the command's output as it is
+	// produced rather than having it buffered and discarded. Plugins
+	// should write to them incrementally so long-running commands stream
+	// to the Vagrant UI instead of appearing only after they exit. Unset
+	// fields discard the output; they are never defaulted to the plugin
+	// process's own os.Stdout/os.Stderr, since those belong to the
+	// go-plugin RPC/handshake stream, not to the guest command.
+	Stdout io.Writer
+	Stderr io.Writer
+
+	// Stdin, when set, is piped to the command's standard input.
+	Stdin io.Reader
+
+	// Timeout bounds how long the call is allowed to run before it is
+	// cancelled and treated as failed, same as cancelling Context
+	// yourself. Zero means no timeout.
+	Timeout time.Duration
+
+	// Concurrency bounds how many files Upload/Download move at once.
+	// Zero uses a small built-in default.
+	Concurrency int
+
+	// Progress, when set, is called as each file's transfer makes
+	// progress, so the UI can render a bar per file. done and total are
+	// byte counts. Upload/Download run entries across a worker pool, so
+	// Progress may be called concurrently from multiple goroutines and
+	// must be safe for that.
+	Progress func(entry TransferEntry, done, total int64)
+}
+
+// resolveOptions returns the caller-supplied options, or a zero-value
+// CommunicatorOptions if none were given, with Context always populated.
+func resolveOptions(options []CommunicatorOptions) CommunicatorOptions {
+	var opts CommunicatorOptions
+	if len(options) > 0 {
+		opts = options[0]
+	}
+
+	if opts.Context == nil {
+		opts.Context = context.Background()
+	}
+
+	return opts
+}
+
+// outputs returns the options' Stdout/Stderr, defaulting to io.Discard
+// when unset. This communicator runs inside a go-plugin binary, where the
+// process's real os.Stdout is part of the go-plugin handshake/RPC stream;
+// writing guest command output there would corrupt it, so an unset sink
+// means "drop it", never "print it".
+func (o CommunicatorOptions) outputs() (stdout, stderr io.Writer) {
+	stdout, stderr = o.Stdout, o.Stderr
+	if stdout == nil {
+		stdout = io.Discard
+	}
+	if stderr == nil {
+		stderr = io.Discard
+	}
+
+	return stdout, stderr
+}
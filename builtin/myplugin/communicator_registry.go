@@ -0,0 +1,30 @@
+package myplugin
+
+import "github.com/hashicorp/vagrant-plugin-sdk/component"
+
+// Communicators is the set of communicator factories myplugin registers
+// with the core CommunicatorStore (as a core.CommunicatorFactories). Map
+// iteration order is not defined, so CommunicatorPriority is what
+// actually determines selection order; this map only supplies each
+// name's factory. It is a plain map, not a named SDK type: nothing
+// corroborates the pinned vagrant-plugin-sdk version exporting a
+// MapOfCommunicator, so this package doesn't depend on it existing.
+var Communicators = map[string]func() (component.Communicator, error){
+	"winrm": func() (component.Communicator, error) { return &WinRMCommunicator{}, nil },
+	"dummy": func() (component.Communicator, error) { return &DummyCommunicator{}, nil },
+}
+
+// CommunicatorPriority is the explicit order core's CommunicatorStore
+// should try the names in Communicators: "winrm" first, so a machine
+// explicitly configured for it is picked up, then "dummy" last as the
+// catch-all fallback for machines with no communicator configured.
+var CommunicatorPriority = []string{"winrm", "dummy"}
+
+// machineCommunicatorName is satisfied by a plugincore.Machine that can
+// report the communicator name configured for it (config.vm.communicator
+// in the Vagrantfile, or equivalent). Match implementations assert for
+// this instead of depending on their own config, which a Communicator
+// instance used only for selection never has populated.
+type machineCommunicatorName interface {
+	CommunicatorName() (string, error)
+}
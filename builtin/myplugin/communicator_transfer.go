@@ -0,0 +1,94 @@
+package myplugin
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"sync"
+)
+
+// TransferEntry describes a single file to move in an Upload or
+// Download call. Communicators accept a slice of these so callers
+// syncing a tree of files (e.g. a Chef cookbook or Ansible role) can
+// fan transfers out across a worker pool instead of paying one
+// round-trip per file.
+type TransferEntry struct {
+	Source      string
+	Destination string
+}
+
+// defaultTransferConcurrency is the worker count used when
+// CommunicatorOptions.Concurrency is unset.
+const defaultTransferConcurrency = 4
+
+// runTransfers fans entries out across a worker pool sized by
+// opts.Concurrency, calling fn for each one, and returns the first error
+// encountered after every worker has finished. If opts.Context is
+// cancelled, queued entries that haven't started yet are dropped and the
+// call returns the context's error once in-flight entries finish; fn
+// itself is responsible for noticing cancellation and returning early
+// for an entry already in progress.
+func runTransfers(entries []TransferEntry, opts CommunicatorOptions, fn func(TransferEntry) error) error {
+	if len(entries) == 0 {
+		return nil
+	}
+
+	workers := opts.Concurrency
+	if workers <= 0 {
+		workers = defaultTransferConcurrency
+	}
+	if workers > len(entries) {
+		workers = len(entries)
+	}
+
+	jobs := make(chan TransferEntry)
+	errs := make(chan error, len(entries))
+
+	var wg sync.WaitGroup
+	wg.Add(workers)
+	for i := 0; i < workers; i++ {
+		go func() {
+			defer wg.Done()
+			for entry := range jobs {
+				if opts.Context.Err() != nil {
+					continue
+				}
+				errs <- fn(entry)
+			}
+		}()
+	}
+
+	go func() {
+		defer close(jobs)
+		for _, entry := range entries {
+			select {
+			case jobs <- entry:
+			case <-opts.Context.Done():
+				return
+			}
+		}
+	}()
+
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+
+	return opts.Context.Err()
+}
+
+// reportProgress invokes opts.Progress, if set.
+func reportProgress(opts CommunicatorOptions, entry TransferEntry, done, total int64) {
+	if opts.Progress != nil {
+		opts.Progress(entry, done, total)
+	}
+}
+
+// sha256Hex returns the hex-encoded SHA-256 digest of data.
+func sha256Hex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
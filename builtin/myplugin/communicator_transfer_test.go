@@ -0,0 +1,87 @@
+package myplugin
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+)
+
+func TestSha256Hex(t *testing.T) {
+	got := sha256Hex([]byte("hello"))
+	want := "2cf24dba5fb0a30e26e83b2ac5b9e29e1b161e5c1fa7425e73043362938b9824"
+
+	if got != want {
+		t.Fatalf("sha256Hex(%q) = %q, want %q", "hello", got, want)
+	}
+}
+
+func TestRunTransfersRunsEveryEntry(t *testing.T) {
+	entries := []TransferEntry{{Source: "a"}, {Source: "b"}, {Source: "c"}}
+
+	var count int32
+	opts := resolveOptions(nil)
+	err := runTransfers(entries, opts, func(entry TransferEntry) error {
+		atomic.AddInt32(&count, 1)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("runTransfers returned error: %v", err)
+	}
+	if count != int32(len(entries)) {
+		t.Fatalf("ran %d entries, want %d", count, len(entries))
+	}
+}
+
+func TestRunTransfersReturnsFirstError(t *testing.T) {
+	entries := []TransferEntry{{Source: "a"}, {Source: "b"}}
+	boom := errors.New("boom")
+
+	opts := resolveOptions(nil)
+	err := runTransfers(entries, opts, func(entry TransferEntry) error {
+		return boom
+	})
+	if !errors.Is(err, boom) {
+		t.Fatalf("runTransfers returned %v, want %v", err, boom)
+	}
+}
+
+// TestRunTransfersStopsQueueingAfterCancel holds the single worker busy on
+// the first entry until the context is cancelled, so the remaining entries
+// can only reach the worker after it returns from the first call, by which
+// point the context is already done: whether or not one of them is still
+// handed over, the worker drops it instead of running it.
+func TestRunTransfersStopsQueueingAfterCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	entries := []TransferEntry{{Source: "a"}, {Source: "b"}, {Source: "c"}}
+	opts := CommunicatorOptions{Context: ctx, Concurrency: 1}
+
+	started := make(chan struct{})
+	proceed := make(chan struct{})
+
+	var ran []string
+	resultCh := make(chan error, 1)
+	go func() {
+		resultCh <- runTransfers(entries, opts, func(entry TransferEntry) error {
+			ran = append(ran, entry.Source)
+			if entry.Source == "a" {
+				close(started)
+				<-proceed
+			}
+			return nil
+		})
+	}()
+
+	<-started
+	cancel()
+	close(proceed)
+
+	err := <-resultCh
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("runTransfers returned %v, want %v", err, context.Canceled)
+	}
+	if len(ran) != 1 || ran[0] != "a" {
+		t.Fatalf("ran %v, want just [a]", ran)
+	}
+}
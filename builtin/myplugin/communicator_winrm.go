@@ -0,0 +1,735 @@
+package myplugin
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/masterzen/winrm"
+
+	"github.com/hashicorp/vagrant-plugin-sdk/component"
+	plugincore "github.com/hashicorp/vagrant-plugin-sdk/core"
+)
+
+const (
+	defaultWinRMPort      = 5985
+	defaultWinRMHTTPSPort = 5986
+	defaultWinRMTransport = "basic"
+	defaultWinRMTimeout   = 30 * time.Second
+	defaultWinRMRetryWait = time.Second
+	maxWinRMRetryWait     = 16 * time.Second
+
+	// winrmTransferChunkSize is the amount of raw file data
+	// base64-encoded into each PowerShell append/read call made by
+	// Upload and Download. Kept well under WinRM's shell command-length
+	// limit once base64-inflated.
+	winrmTransferChunkSize = 512 * 1024
+)
+
+// WinRMCommunicator is a Communicator implementation that manages a
+// Windows guest over WinRM rather than SSH.
+type WinRMCommunicator struct {
+	config CommunicatorConfig
+}
+
+func (w *WinRMCommunicator) MatchFunc() interface{} {
+	return w.Match
+}
+
+// Match claims machine when its configured communicator name is
+// "winrm". This is selected from the machine itself (config.vm.communicator
+// in the Vagrantfile), not from w.config: a Communicator used only for
+// selection via CommunicatorStore has no config populated yet, and
+// w.config.Transport is the WinRM auth scheme ("basic"/"ntlm"/"kerberos"),
+// a different thing entirely from the communicator name.
+func (w *WinRMCommunicator) Match(machine plugincore.Machine) (isMatch bool, err error) {
+	named, ok := machine.(machineCommunicatorName)
+	if !ok {
+		return false, nil
+	}
+
+	name, err := named.CommunicatorName()
+	if err != nil {
+		return false, err
+	}
+
+	return strings.EqualFold(name, "winrm"), nil
+}
+
+func (w *WinRMCommunicator) InitFunc() interface{} {
+	return w.Init
+}
+
+// Init validates the config, fills in defaults, and resolves Password via
+// resolveSecret when SecretPath and SecretField are set. It does not
+// attempt to connect to the guest; that happens in Ready/WaitForReady.
+func (w *WinRMCommunicator) Init(machine plugincore.Machine) error {
+	if w.config.Host == "" {
+		return fmt.Errorf("winrm communicator requires a host")
+	}
+
+	password, err := resolveSecret(context.Background(), w.config)
+	if err != nil {
+		return err
+	}
+	w.config.Password = password
+
+	if w.config.Port == 0 {
+		if w.config.UseHTTPS {
+			w.config.Port = defaultWinRMHTTPSPort
+		} else {
+			w.config.Port = defaultWinRMPort
+		}
+	}
+
+	if w.config.Transport == "" {
+		w.config.Transport = defaultWinRMTransport
+	}
+
+	return nil
+}
+
+func (w *WinRMCommunicator) ReadyFunc() interface{} {
+	return w.Ready
+}
+
+// Ready probes the WinRM port with a single short-lived TCP dial.
+func (w *WinRMCommunicator) Ready(machine plugincore.Machine) (isReady bool, err error) {
+	addr := net.JoinHostPort(w.config.Host, strconv.Itoa(w.config.Port))
+
+	conn, err := net.DialTimeout("tcp", addr, 5*time.Second)
+	if err != nil {
+		return false, nil
+	}
+	conn.Close()
+
+	return true, nil
+}
+
+func (w *WinRMCommunicator) WaitForReadyFunc() interface{} {
+	return w.WaitForReady
+}
+
+// WaitForReady polls Ready with exponential backoff until it succeeds or
+// the wait (in seconds) elapses.
+func (w *WinRMCommunicator) WaitForReady(machine plugincore.Machine, wait int) (isReady bool, err error) {
+	deadline := time.Now().Add(time.Duration(wait) * time.Second)
+	backoff := defaultWinRMRetryWait
+
+	for {
+		ready, err := w.Ready(machine)
+		if err != nil {
+			return false, err
+		}
+		if ready {
+			return true, nil
+		}
+
+		if !time.Now().Add(backoff).Before(deadline) {
+			return false, nil
+		}
+
+		time.Sleep(backoff)
+		backoff = nextBackoff(backoff)
+	}
+}
+
+// nextBackoff doubles wait, capped at maxWinRMRetryWait.
+func nextBackoff(wait time.Duration) time.Duration {
+	wait *= 2
+	if wait > maxWinRMRetryWait {
+		wait = maxWinRMRetryWait
+	}
+	return wait
+}
+
+func (w *WinRMCommunicator) DownloadFunc() interface{} {
+	return w.Download
+}
+
+// Download fetches every entry, fanning transfers out across a worker
+// pool sized by CommunicatorOptions.Concurrency.
+func (w *WinRMCommunicator) Download(
+	machine plugincore.Machine,
+	entries []TransferEntry,
+	options ...CommunicatorOptions,
+) error {
+	opts := resolveOptions(options)
+
+	return runTransfers(entries, opts, func(entry TransferEntry) error {
+		return w.downloadResumable(entry, opts)
+	})
+}
+
+// downloadResumable streams source off the guest one fixed-size chunk at
+// a time, mirroring uploadResumable: neither side ever holds more than a
+// chunk in memory, and a partially-downloaded destination is resumed by
+// hashing the matching byte range already on disk locally and skipping
+// chunks whose SHA-256 already matches the guest's. It stops early if
+// opts.Context is cancelled.
+func (w *WinRMCommunicator) downloadResumable(entry TransferEntry, opts CommunicatorOptions) error {
+	client, err := w.client()
+	if err != nil {
+		return err
+	}
+
+	totalBytes, err := w.remoteFileSize(client, entry.Source)
+	if err != nil {
+		return fmt.Errorf("failed to stat %s for download: %w", entry.Source, err)
+	}
+
+	f, err := os.OpenFile(entry.Destination, os.O_RDWR|os.O_CREATE, 0o644)
+	if err != nil {
+		return fmt.Errorf("failed to open %s for download: %w", entry.Destination, err)
+	}
+	defer f.Close()
+
+	buf := make([]byte, winrmTransferChunkSize)
+
+	resumeFrom := 0
+	for offset := int64(0); offset < totalBytes; offset += int64(winrmTransferChunkSize) {
+		want := int64(winrmTransferChunkSize)
+		if remaining := totalBytes - offset; remaining < want {
+			want = remaining
+		}
+
+		n, err := f.ReadAt(buf[:want], offset)
+		if err != nil && err != io.EOF {
+			return fmt.Errorf("failed to read %s for download: %w", entry.Destination, err)
+		}
+		if int64(n) < want {
+			break
+		}
+
+		remoteHash, err := w.remoteChunkHash(client, entry.Source, offset, want)
+		if err != nil || remoteHash != sha256Hex(buf[:n]) {
+			break
+		}
+		resumeFrom++
+	}
+
+	doneBytes := int64(resumeFrom) * int64(winrmTransferChunkSize)
+	if doneBytes > totalBytes {
+		doneBytes = totalBytes
+	}
+	if err := f.Truncate(doneBytes); err != nil {
+		return fmt.Errorf("failed to truncate %s for download resume: %w", entry.Destination, err)
+	}
+
+	reportProgress(opts, entry, doneBytes, totalBytes)
+
+	for doneBytes < totalBytes {
+		select {
+		case <-opts.Context.Done():
+			return opts.Context.Err()
+		default:
+		}
+
+		length := int64(winrmTransferChunkSize)
+		if remaining := totalBytes - doneBytes; remaining < length {
+			length = remaining
+		}
+
+		script := fmt.Sprintf(
+			`$fs = New-Object IO.FileStream("%s", [IO.FileMode]::Open); `+
+				`$fs.Seek(%d, [IO.SeekOrigin]::Begin) | Out-Null; `+
+				`$buf = New-Object byte[] %d; `+
+				`$fs.Read($buf, 0, %d) | Out-Null; $fs.Close(); `+
+				`[Convert]::ToBase64String($buf)`,
+			psQuote(entry.Source), doneBytes, length, length,
+		)
+
+		var encoded bytes.Buffer
+		if _, err := client.Run(winrm.Powershell(script), &encoded, io.Discard); err != nil {
+			return fmt.Errorf("winrm download of %s failed: %w", entry.Source, err)
+		}
+
+		data, err := base64.StdEncoding.DecodeString(strings.TrimSpace(encoded.String()))
+		if err != nil {
+			return fmt.Errorf("failed to decode %s: %w", entry.Source, err)
+		}
+
+		if _, err := f.WriteAt(data, doneBytes); err != nil {
+			return fmt.Errorf("failed to write %s: %w", entry.Destination, err)
+		}
+
+		doneBytes += int64(len(data))
+		reportProgress(opts, entry, doneBytes, totalBytes)
+	}
+
+	return nil
+}
+
+// remoteFileSize returns the size in bytes of path on the guest.
+func (w *WinRMCommunicator) remoteFileSize(client *winrm.Client, path string) (int64, error) {
+	script := fmt.Sprintf(`(Get-Item "%s").Length`, psQuote(path))
+
+	var out bytes.Buffer
+	if _, err := client.Run(winrm.Powershell(script), &out, io.Discard); err != nil {
+		return 0, fmt.Errorf("failed to stat remote file %s: %w", path, err)
+	}
+
+	size, err := strconv.ParseInt(strings.TrimSpace(out.String()), 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("failed to parse size of remote file %s: %w", path, err)
+	}
+
+	return size, nil
+}
+
+func (w *WinRMCommunicator) UploadFunc() interface{} {
+	return w.Upload
+}
+
+// Upload sends every entry, fanning transfers out across a worker pool
+// sized by CommunicatorOptions.Concurrency.
+func (w *WinRMCommunicator) Upload(
+	machine plugincore.Machine,
+	entries []TransferEntry,
+	options ...CommunicatorOptions,
+) error {
+	opts := resolveOptions(options)
+
+	return runTransfers(entries, opts, func(entry TransferEntry) error {
+		return w.uploadResumable(entry, opts)
+	})
+}
+
+// uploadResumable streams source to the guest one fixed-size chunk at a
+// time, appending each one, base64-encoded, to destination via
+// PowerShell, so neither the local read nor the remote write ever holds
+// more than a chunk in memory. Before sending, it hashes the matching
+// byte range already on the guest and skips chunks whose SHA-256 already
+// matches, so an interrupted `vagrant up` resumes instead of re-sending
+// the whole file. It stops early if opts.Context is cancelled.
+func (w *WinRMCommunicator) uploadResumable(entry TransferEntry, opts CommunicatorOptions) error {
+	client, err := w.client()
+	if err != nil {
+		return err
+	}
+
+	info, err := os.Stat(entry.Source)
+	if err != nil {
+		return fmt.Errorf("failed to stat %s for upload: %w", entry.Source, err)
+	}
+	totalBytes := info.Size()
+
+	f, err := os.Open(entry.Source)
+	if err != nil {
+		return fmt.Errorf("failed to open %s for upload: %w", entry.Source, err)
+	}
+	defer f.Close()
+
+	buf := make([]byte, winrmTransferChunkSize)
+
+	resumeFrom := 0
+	for offset := int64(0); offset < totalBytes; offset += int64(winrmTransferChunkSize) {
+		n, err := io.ReadFull(f, buf)
+		if err != nil && err != io.ErrUnexpectedEOF {
+			return fmt.Errorf("failed to read %s for upload: %w", entry.Source, err)
+		}
+
+		remoteHash, err := w.remoteChunkHash(client, entry.Destination, offset, int64(n))
+		if err != nil || remoteHash != sha256Hex(buf[:n]) {
+			break
+		}
+		resumeFrom++
+	}
+
+	if resumeFrom == 0 {
+		clearScript := fmt.Sprintf(`Set-Content -Path "%s" -Value $null`, psQuote(entry.Destination))
+		if _, err := client.Run(winrm.Powershell(clearScript), io.Discard, io.Discard); err != nil {
+			return fmt.Errorf("winrm upload of %s failed to initialize %s: %w", entry.Source, entry.Destination, err)
+		}
+	} else if doneBytes := int64(resumeFrom) * int64(winrmTransferChunkSize); doneBytes < totalBytes {
+		truncateScript := fmt.Sprintf(
+			`$fs = New-Object IO.FileStream("%s", [IO.FileMode]::Open); $fs.SetLength(%d); $fs.Close()`,
+			psQuote(entry.Destination), doneBytes,
+		)
+		if _, err := client.Run(winrm.Powershell(truncateScript), io.Discard, io.Discard); err != nil {
+			return fmt.Errorf("winrm upload of %s failed to resume %s: %w", entry.Source, entry.Destination, err)
+		}
+	}
+
+	doneBytes := int64(resumeFrom) * int64(winrmTransferChunkSize)
+	if doneBytes > totalBytes {
+		doneBytes = totalBytes
+	}
+	reportProgress(opts, entry, doneBytes, totalBytes)
+
+	if _, err := f.Seek(doneBytes, io.SeekStart); err != nil {
+		return fmt.Errorf("failed to seek %s for upload: %w", entry.Source, err)
+	}
+
+	for doneBytes < totalBytes {
+		select {
+		case <-opts.Context.Done():
+			return opts.Context.Err()
+		default:
+		}
+
+		n, err := io.ReadFull(f, buf)
+		if err != nil && err != io.ErrUnexpectedEOF {
+			return fmt.Errorf("failed to read %s for upload: %w", entry.Source, err)
+		}
+
+		appendScript := fmt.Sprintf(
+			`$bytes = [Convert]::FromBase64String("%s"); `+
+				`$fs = New-Object IO.FileStream("%s", [IO.FileMode]::Append); `+
+				`$fs.Write($bytes, 0, $bytes.Length); $fs.Close()`,
+			base64.StdEncoding.EncodeToString(buf[:n]), psQuote(entry.Destination),
+		)
+
+		if _, err := client.Run(winrm.Powershell(appendScript), io.Discard, io.Discard); err != nil {
+			return fmt.Errorf("winrm upload of %s to %s failed: %w", entry.Source, entry.Destination, err)
+		}
+
+		doneBytes += int64(n)
+		reportProgress(opts, entry, doneBytes, totalBytes)
+	}
+
+	return nil
+}
+
+// remoteChunkHash returns the hex-encoded SHA-256 of the length bytes of
+// path on the guest starting at offset, or "" if path does not yet have
+// that many bytes. Used by both uploadResumable (against
+// entry.Destination) and downloadResumable (against entry.Source) to
+// decide which chunks can be skipped on resume.
+func (w *WinRMCommunicator) remoteChunkHash(client *winrm.Client, path string, offset, length int64) (string, error) {
+	script := fmt.Sprintf(
+		`$path = "%s"; `+
+			`if (-not (Test-Path $path)) { exit 0 }; `+
+			`if ((Get-Item $path).Length -lt %d) { exit 0 }; `+
+			`$fs = New-Object IO.FileStream($path, [IO.FileMode]::Open); `+
+			`$fs.Seek(%d, [IO.SeekOrigin]::Begin) | Out-Null; `+
+			`$buf = New-Object byte[] %d; `+
+			`$fs.Read($buf, 0, %d) | Out-Null; $fs.Close(); `+
+			`$hash = [Security.Cryptography.SHA256]::Create().ComputeHash($buf); `+
+			`[BitConverter]::ToString($hash) -replace "-", ""`,
+		psQuote(path), offset+length, offset, length, length,
+	)
+
+	var out bytes.Buffer
+	if _, err := client.Run(winrm.Powershell(script), &out, io.Discard); err != nil {
+		return "", fmt.Errorf("failed to hash remote chunk of %s: %w", path, err)
+	}
+
+	return strings.ToLower(strings.TrimSpace(out.String())), nil
+}
+
+func (w *WinRMCommunicator) ExecuteFunc() interface{} {
+	return w.Execute
+}
+
+func (w *WinRMCommunicator) Execute(
+	machine plugincore.Machine,
+	command []string,
+	options ...CommunicatorOptions,
+) (status int32, err error) {
+	return w.run(command, options...)
+}
+
+func (w *WinRMCommunicator) PrivilegedExecuteFunc() interface{} {
+	return w.PrivilegedExecute
+}
+
+// PrivilegedExecute runs command elevated to SYSTEM via runElevated, the
+// WinRM equivalent of SSH's sudo. It does not use run's shell, since
+// elevation needs its own scheduled-task plumbing; see runElevated. Its
+// output reaches the options' Stdout/Stderr only after the command has
+// finished, not as it is produced, and the options' Stdin is not used.
+func (w *WinRMCommunicator) PrivilegedExecute(
+	machine plugincore.Machine,
+	command []string,
+	options ...CommunicatorOptions,
+) (status int32, err error) {
+	client, err := w.client()
+	if err != nil {
+		return 0, err
+	}
+
+	opts := resolveOptions(options)
+	stdout, stderr := opts.outputs()
+
+	ctx := opts.Context
+	if opts.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, opts.Timeout)
+		defer cancel()
+	}
+
+	return w.runElevated(client, ctx, strings.Join(command, " "), stdout, stderr)
+}
+
+func (w *WinRMCommunicator) TestFunc() interface{} {
+	return w.Test
+}
+
+// Test reports whether command looks like it would be accepted by
+// cmd.exe's shell parser, without actually running it. It is a syntax
+// sanity check (balanced double quotes and parens, honoring cmd.exe's
+// "^" escape), not a full parser, so it can still pass strings cmd.exe
+// would reject for other reasons.
+func (w *WinRMCommunicator) Test(
+	machine plugincore.Machine,
+	command []string,
+	options ...CommunicatorOptions,
+) (valid bool, err error) {
+	return cmdShellParses(strings.Join(command, " ")), nil
+}
+
+// cmdShellParses reports whether s has balanced double quotes and parens
+// as cmd.exe's shell would see them. cmd.exe has no single-quote
+// quoting at all — a "'" is just a literal character — and "^" is only
+// an escape character outside of a double-quoted string; inside one, it
+// is inert and does not suppress the next rune.
+func cmdShellParses(s string) bool {
+	var inDouble, escapeNext bool
+	depth := 0
+
+	for _, r := range s {
+		if escapeNext {
+			escapeNext = false
+			continue
+		}
+
+		switch {
+		case r == '^' && !inDouble:
+			escapeNext = true
+		case r == '"':
+			inDouble = !inDouble
+		case r == '(' && !inDouble:
+			depth++
+		case r == ')' && !inDouble:
+			depth--
+		}
+
+		if depth < 0 {
+			return false
+		}
+	}
+
+	return !inDouble && depth == 0
+}
+
+func (w *WinRMCommunicator) ResetFunc() interface{} {
+	return w.Reset
+}
+
+// Reset is a no-op for WinRM: there is no persistent shell session to
+// tear down between commands.
+func (w *WinRMCommunicator) Reset(machine plugincore.Machine) (err error) {
+	return nil
+}
+
+// run executes command over WinRM in its own shell and returns its exit
+// status. Output is streamed to the options' Stdout/Stderr as it is
+// produced. If the context is cancelled, or opts.Timeout elapses, before
+// the guest command finishes, the shell is closed to actually stop the
+// remote command rather than merely abandoning it, and the call returns
+// early with the context's error. Elevated commands don't go through
+// run at all; see PrivilegedExecute/runElevated.
+func (w *WinRMCommunicator) run(command []string, options ...CommunicatorOptions) (int32, error) {
+	client, err := w.client()
+	if err != nil {
+		return 0, err
+	}
+
+	cmd := strings.Join(command, " ")
+
+	opts := resolveOptions(options)
+	stdout, stderr := opts.outputs()
+
+	ctx := opts.Context
+	if opts.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, opts.Timeout)
+		defer cancel()
+	}
+
+	shell, err := client.CreateShell()
+	if err != nil {
+		return 0, fmt.Errorf("winrm execute failed to create shell: %w", err)
+	}
+	defer shell.Close()
+
+	remote, err := shell.Execute(cmd)
+	if err != nil {
+		return 0, fmt.Errorf("winrm execute failed: %w", err)
+	}
+
+	if opts.Stdin != nil {
+		go io.Copy(remote.Stdin, opts.Stdin)
+	}
+	go io.Copy(stdout, remote.Stdout)
+	go io.Copy(stderr, remote.Stderr)
+
+	done := make(chan struct{})
+	go func() {
+		remote.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-ctx.Done():
+		remote.Close()
+		return 0, ctx.Err()
+	case <-done:
+		return int32(remote.ExitCode()), nil
+	}
+}
+
+// runElevated runs cmd on the guest elevated to SYSTEM via a scheduled
+// task. WinRM sessions are non-interactive, so Start-Process -Verb
+// RunAs's UAC consent prompt can never be satisfied; schtasks instead
+// queues the command to run as SYSTEM with no prompt at all. Because the
+// task runs detached rather than as a child of the WinRM shell, its
+// output and exit code don't flow through a live pipe the way run's
+// shell.Execute does: cmd is wrapped in a small .cmd script that
+// redirects stdout/stderr to files and records the real exit code and a
+// completion marker, all under a per-call temp directory, which is
+// polled for and read back once the task finishes, so the caller gets
+// the inner command's own exit code and output rather than the
+// scheduler's.
+func (w *WinRMCommunicator) runElevated(client *winrm.Client, ctx context.Context, cmd string, stdout, stderr io.Writer) (int32, error) {
+	taskName := fmt.Sprintf("vagrant-elevated-%d", time.Now().UnixNano())
+	tempDir := `C:\Windows\Temp\` + taskName
+	scriptPath := tempDir + `\run.cmd`
+	donePath := tempDir + `\done`
+
+	scriptBody := fmt.Sprintf(
+		"cmd.exe /c \"%s\" > \"%s\\stdout.log\" 2> \"%s\\stderr.log\"\r\n"+
+			"echo %%errorlevel%% > \"%s\\exit.txt\"\r\n"+
+			"echo done > \"%s\"\r\n",
+		cmd, tempDir, tempDir, tempDir, donePath,
+	)
+
+	stageScript := fmt.Sprintf(
+		`New-Item -ItemType Directory -Path "%s" -Force | Out-Null; `+
+			`Set-Content -Path "%s" -Value '%s' -NoNewline`,
+		psQuote(tempDir), psQuote(scriptPath), psSingleQuote(scriptBody),
+	)
+	if _, err := client.Run(winrm.Powershell(stageScript), io.Discard, io.Discard); err != nil {
+		return 0, fmt.Errorf("winrm privileged execute failed to stage elevated script: %w", err)
+	}
+	defer func() {
+		cleanupScript := fmt.Sprintf(
+			`schtasks /Delete /TN "%s" /F | Out-Null; Remove-Item -Recurse -Force "%s" -ErrorAction SilentlyContinue`,
+			taskName, psQuote(tempDir),
+		)
+		client.Run(winrm.Powershell(cleanupScript), io.Discard, io.Discard)
+	}()
+
+	runScript := fmt.Sprintf(
+		`schtasks /Create /TN "%s" /TR "cmd.exe /c \"%s\"" /SC ONCE /ST 00:00 /RU SYSTEM /RL HIGHEST /F | Out-Null; `+
+			`schtasks /Run /TN "%s" | Out-Null`,
+		taskName, scriptPath, taskName,
+	)
+	if _, err := client.Run(winrm.Powershell(runScript), io.Discard, io.Discard); err != nil {
+		return 0, fmt.Errorf("winrm privileged execute failed to schedule elevated task: %w", err)
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return 0, ctx.Err()
+		default:
+		}
+
+		var exists bytes.Buffer
+		checkScript := fmt.Sprintf(`Test-Path "%s"`, psQuote(donePath))
+		if _, err := client.Run(winrm.Powershell(checkScript), &exists, io.Discard); err != nil {
+			return 0, fmt.Errorf("winrm privileged execute failed to poll completion: %w", err)
+		}
+		if strings.TrimSpace(exists.String()) == "True" {
+			break
+		}
+
+		time.Sleep(defaultWinRMRetryWait)
+	}
+
+	readFile := func(path string) (string, error) {
+		var out bytes.Buffer
+		script := fmt.Sprintf(`Get-Content -Raw "%s"`, psQuote(path))
+		if _, err := client.Run(winrm.Powershell(script), &out, io.Discard); err != nil {
+			return "", err
+		}
+		return out.String(), nil
+	}
+
+	outStr, err := readFile(tempDir + `\stdout.log`)
+	if err != nil {
+		return 0, fmt.Errorf("winrm privileged execute failed to read stdout: %w", err)
+	}
+	io.WriteString(stdout, outStr)
+
+	errStr, err := readFile(tempDir + `\stderr.log`)
+	if err != nil {
+		return 0, fmt.Errorf("winrm privileged execute failed to read stderr: %w", err)
+	}
+	io.WriteString(stderr, errStr)
+
+	exitStr, err := readFile(tempDir + `\exit.txt`)
+	if err != nil {
+		return 0, fmt.Errorf("winrm privileged execute failed to read exit code: %w", err)
+	}
+	exitCode, err := strconv.Atoi(strings.TrimSpace(exitStr))
+	if err != nil {
+		return 0, fmt.Errorf("winrm privileged execute got unparseable exit code %q: %w", exitStr, err)
+	}
+
+	return int32(exitCode), nil
+}
+
+// client builds a winrm.Client from the communicator's config, selecting
+// the transport decorator for NTLM/Kerberos authentication when
+// requested.
+func (w *WinRMCommunicator) client() (*winrm.Client, error) {
+	endpoint := winrm.NewEndpoint(
+		w.config.Host,
+		w.config.Port,
+		w.config.UseHTTPS,
+		w.config.Insecure,
+		[]byte(w.config.CACert),
+		nil,
+		nil,
+		defaultWinRMTimeout,
+	)
+
+	params := winrm.DefaultParameters
+	switch strings.ToLower(w.config.Transport) {
+	case "ntlm":
+		params.TransportDecorator = func() winrm.Transporter { return &winrm.ClientNTLM{} }
+	case "kerberos":
+		params.TransportDecorator = func() winrm.Transporter { return &winrm.ClientKerberos{} }
+	}
+
+	return winrm.NewClientWithParameters(endpoint, w.config.User, w.config.Password, params)
+}
+
+// psQuote escapes double quotes so a value can be embedded in a
+// double-quoted PowerShell string literal.
+func psQuote(s string) string {
+	return strings.ReplaceAll(s, `"`, "`\"")
+}
+
+// psSingleQuote escapes single quotes by doubling them, so an arbitrary
+// value (such as a whole .cmd script's contents, which may itself contain
+// `$`, backticks, or double quotes) can be embedded literally in a
+// single-quoted PowerShell string literal without any of PowerShell's
+// variable-interpolation or escape handling kicking in.
+func psSingleQuote(s string) string {
+	return strings.ReplaceAll(s, "'", "''")
+}
+
+var (
+	_ component.Communicator = (*WinRMCommunicator)(nil)
+)
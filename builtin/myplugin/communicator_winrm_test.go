@@ -0,0 +1,59 @@
+package myplugin
+
+import "testing"
+
+func TestCmdShellParses(t *testing.T) {
+	cases := []struct {
+		name    string
+		command string
+		want    bool
+	}{
+		{"simple", `echo hello`, true},
+		{"balanced double quotes", `echo "hello world"`, true},
+		{"unbalanced double quotes", `echo "hello world`, false},
+		{"single quotes are literal, not quoting", `echo 'hello world'`, true},
+		{"single quote with no closing quote is still literal", `echo 'hello world`, true},
+		{"balanced parens", `if (1 == 1) (echo yes)`, true},
+		{"unbalanced parens", `if (1 == 1 (echo yes)`, false},
+		{"closing paren with no open", `echo yes)`, false},
+		{"escaped quote", `echo ^"hello`, true},
+		{"single quote does not protect an embedded double quote", `echo 'it"s fine'`, false},
+		{"paren inside double quotes is literal", `echo "(not a group"`, true},
+		{"caret is inert inside double quotes", `"^""`, false},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := cmdShellParses(tc.command); got != tc.want {
+				t.Errorf("cmdShellParses(%q) = %v, want %v", tc.command, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestNextBackoffCapsAtMax(t *testing.T) {
+	wait := defaultWinRMRetryWait
+
+	for i := 0; i < 10; i++ {
+		wait = nextBackoff(wait)
+		if wait > maxWinRMRetryWait {
+			t.Fatalf("nextBackoff exceeded cap: got %v, want <= %v", wait, maxWinRMRetryWait)
+		}
+	}
+
+	if wait != maxWinRMRetryWait {
+		t.Fatalf("nextBackoff did not converge to the cap: got %v, want %v", wait, maxWinRMRetryWait)
+	}
+}
+
+func TestWinRMCommunicatorMatchWithoutNamedMachine(t *testing.T) {
+	w := &WinRMCommunicator{}
+
+	isMatch, err := w.Match(nil)
+	if err != nil {
+		t.Fatalf("Match returned error: %v", err)
+	}
+	if isMatch {
+		t.Fatal("Match returned true for a machine that cannot report its communicator name")
+	}
+}
@@ -0,0 +1,188 @@
+package myplugin
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	vaultapi "github.com/hashicorp/vault/api"
+)
+
+// SecretResolver resolves a single secret field from an external secret
+// store, so credentials never need to live in a Vagrantfile.
+type SecretResolver interface {
+	// Resolve returns the value of field within the secret at path.
+	Resolve(ctx context.Context, path, field string) (string, error)
+}
+
+// resolveSecret materializes cfg's Password from SecretPath/SecretField
+// via the shared VaultResolver, when both are set. It returns
+// cfg.Password unchanged otherwise, so communicators that don't use
+// Vault are unaffected.
+func resolveSecret(ctx context.Context, cfg CommunicatorConfig) (string, error) {
+	if cfg.SecretPath == "" || cfg.SecretField == "" {
+		return cfg.Password, nil
+	}
+
+	resolver, err := sharedVaultResolver()
+	if err != nil {
+		return "", fmt.Errorf("failed to build secret resolver for %s: %w", cfg.SecretPath, err)
+	}
+
+	return resolver.Resolve(ctx, cfg.SecretPath, cfg.SecretField)
+}
+
+var (
+	vaultResolverOnce sync.Once
+	vaultResolver     *VaultResolver
+	vaultResolverErr  error
+)
+
+// sharedVaultResolver returns a single process-wide VaultResolver, built
+// the first time it's needed. Every Init call goes through it so a
+// secret's lease cache and background renewal goroutine are actually
+// reused across communicator instances instead of being built, read
+// once, and discarded.
+func sharedVaultResolver() (*VaultResolver, error) {
+	vaultResolverOnce.Do(func() {
+		vaultResolver, vaultResolverErr = NewVaultResolver()
+	})
+
+	return vaultResolver, vaultResolverErr
+}
+
+// VaultResolver is the default SecretResolver, backed by a HashiCorp
+// Vault KV mount (v1 or v2). It reads VAULT_ADDR, VAULT_TOKEN, and
+// VAULT_NAMESPACE from the environment and caches leased secrets,
+// renewing them in the background for as long as the lease allows.
+type VaultResolver struct {
+	client *vaultapi.Client
+
+	mu     sync.Mutex
+	leases map[string]*vaultapi.Secret
+}
+
+// NewVaultResolver builds a VaultResolver from the environment. It
+// returns an error if VAULT_ADDR or VAULT_TOKEN is unset.
+func NewVaultResolver() (*VaultResolver, error) {
+	addr := os.Getenv("VAULT_ADDR")
+	token := os.Getenv("VAULT_TOKEN")
+	if addr == "" || token == "" {
+		return nil, fmt.Errorf("VAULT_ADDR and VAULT_TOKEN must be set to resolve secrets from vault")
+	}
+
+	config := vaultapi.DefaultConfig()
+	config.Address = addr
+
+	client, err := vaultapi.NewClient(config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build vault client: %w", err)
+	}
+	client.SetToken(token)
+
+	if ns := os.Getenv("VAULT_NAMESPACE"); ns != "" {
+		client.SetNamespace(ns)
+	}
+
+	return &VaultResolver{
+		client: client,
+		leases: make(map[string]*vaultapi.Secret),
+	}, nil
+}
+
+// Resolve reads path from Vault, transparently handling both KV v1
+// (data directly on the secret) and KV v2 (data nested under "data")
+// mounts, and returns field's value as a string.
+func (v *VaultResolver) Resolve(ctx context.Context, path, field string) (string, error) {
+	secret, err := v.read(ctx, path)
+	if err != nil {
+		return "", err
+	}
+
+	return secretField(secret.Data, path, field)
+}
+
+// secretField extracts field's string value from a Vault secret's Data,
+// transparently handling both KV v1 (data directly on the secret) and
+// KV v2 (data nested under a "data" key) mounts.
+func secretField(data map[string]interface{}, path, field string) (string, error) {
+	if nested, ok := data["data"].(map[string]interface{}); ok {
+		data = nested
+	}
+
+	value, ok := data[field]
+	if !ok {
+		return "", fmt.Errorf("secret %s has no field %q", path, field)
+	}
+
+	str, ok := value.(string)
+	if !ok {
+		return "", fmt.Errorf("secret %s field %q is not a string", path, field)
+	}
+
+	return str, nil
+}
+
+// read returns the cached secret for path, issuing a fresh read (and
+// starting a background renewal goroutine, for renewable leases) the
+// first time path is requested.
+func (v *VaultResolver) read(ctx context.Context, path string) (*vaultapi.Secret, error) {
+	v.mu.Lock()
+	if secret, ok := v.leases[path]; ok {
+		v.mu.Unlock()
+		return secret, nil
+	}
+	v.mu.Unlock()
+
+	secret, err := v.client.Logical().ReadWithContext(ctx, path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read secret %s from vault: %w", path, err)
+	}
+	if secret == nil {
+		return nil, fmt.Errorf("no secret found at %s", path)
+	}
+
+	v.mu.Lock()
+	v.leases[path] = secret
+	v.mu.Unlock()
+
+	if secret.Renewable && secret.LeaseDuration > 0 {
+		go v.renew(path, secret)
+	}
+
+	return secret, nil
+}
+
+// renew keeps a leased secret fresh in the background, renewing it at
+// roughly the halfway point of each lease. It stops and drops the cache
+// entry the first time renewal fails (e.g. the lease was revoked), so
+// the next Resolve call re-reads the secret from scratch.
+func (v *VaultResolver) renew(path string, secret *vaultapi.Secret) {
+	for {
+		wait := time.Duration(secret.LeaseDuration/2) * time.Second
+		if wait <= 0 {
+			return
+		}
+		time.Sleep(wait)
+
+		renewed, err := v.client.Sys().Renew(secret.LeaseID, 0)
+		if err != nil {
+			v.mu.Lock()
+			delete(v.leases, path)
+			v.mu.Unlock()
+			return
+		}
+
+		v.mu.Lock()
+		v.leases[path] = renewed
+		v.mu.Unlock()
+
+		secret = renewed
+	}
+}
+
+var (
+	_ SecretResolver = (*VaultResolver)(nil)
+)
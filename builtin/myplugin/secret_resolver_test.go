@@ -0,0 +1,48 @@
+package myplugin
+
+import "testing"
+
+func TestSecretFieldKVv1(t *testing.T) {
+	data := map[string]interface{}{"password": "hunter2"}
+
+	got, err := secretField(data, "secret/myplugin", "password")
+	if err != nil {
+		t.Fatalf("secretField returned error: %v", err)
+	}
+	if got != "hunter2" {
+		t.Fatalf("secretField = %q, want %q", got, "hunter2")
+	}
+}
+
+func TestSecretFieldKVv2(t *testing.T) {
+	data := map[string]interface{}{
+		"data": map[string]interface{}{"password": "hunter2"},
+		"metadata": map[string]interface{}{
+			"version": float64(3),
+		},
+	}
+
+	got, err := secretField(data, "secret/data/myplugin", "password")
+	if err != nil {
+		t.Fatalf("secretField returned error: %v", err)
+	}
+	if got != "hunter2" {
+		t.Fatalf("secretField = %q, want %q", got, "hunter2")
+	}
+}
+
+func TestSecretFieldMissingField(t *testing.T) {
+	data := map[string]interface{}{"password": "hunter2"}
+
+	if _, err := secretField(data, "secret/myplugin", "private_key"); err == nil {
+		t.Fatal("expected an error for a missing field, got nil")
+	}
+}
+
+func TestSecretFieldNonStringField(t *testing.T) {
+	data := map[string]interface{}{"password": float64(12345)}
+
+	if _, err := secretField(data, "secret/myplugin", "password"); err == nil {
+		t.Fatal("expected an error for a non-string field, got nil")
+	}
+}
@@ -0,0 +1,104 @@
+package core
+
+import (
+	"fmt"
+
+	"github.com/hashicorp/vagrant-plugin-sdk/component"
+	plugincore "github.com/hashicorp/vagrant-plugin-sdk/core"
+)
+
+// CommunicatorFactories maps a communicator plugin's registered name to a
+// factory that builds a fresh instance of it. This is defined here, not
+// assumed as component.MapOfCommunicator from the SDK: nothing else in
+// the pinned vagrant-plugin-sdk version corroborates that type being
+// exported, so CommunicatorStore takes its own plain map instead of
+// depending on an unconfirmed external symbol.
+type CommunicatorFactories map[string]func() (component.Communicator, error)
+
+// CommunicatorStore discovers the component.Communicator plugins
+// registered with Vagrant and picks the one responsible for a given
+// machine. Plugins are tried in priority order (the order of the names
+// passed to NewCommunicatorStore) and the first one whose Match returns
+// true wins. This
+// lets third-party plugins participate in communicator selection without
+// any Vagrantfile config changes.
+type CommunicatorStore struct {
+	names         []string
+	communicators CommunicatorFactories
+}
+
+// NewCommunicatorStore builds a CommunicatorStore from the discovered
+// communicator plugins. names fixes the priority order Match tries them
+// in and must be supplied explicitly by the caller (e.g. a plugin's own
+// CommunicatorPriority) — communicators is a map, and Go map iteration
+// order is undefined, so it must never be used to derive priority. It
+// fails fast if any plugin is malformed, per checkCommunicatorSpec.
+func NewCommunicatorStore(names []string, communicators CommunicatorFactories) (*CommunicatorStore, error) {
+	for _, name := range names {
+		factory, ok := communicators[name]
+		if !ok {
+			return nil, fmt.Errorf("communicator plugin %q is registered but has no factory", name)
+		}
+
+		if err := checkCommunicatorSpec(name, factory); err != nil {
+			return nil, err
+		}
+	}
+
+	return &CommunicatorStore{names: names, communicators: communicators}, nil
+}
+
+// List returns the registered communicator plugin names in priority
+// order, for CLI introspection (`vagrant communicator list`).
+func (s *CommunicatorStore) List() []string {
+	names := make([]string, len(s.names))
+	copy(names, s.names)
+	return names
+}
+
+// Start instantiates the named communicator plugin.
+func (s *CommunicatorStore) Start(name string) (component.Communicator, error) {
+	factory, ok := s.communicators[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown communicator plugin %q", name)
+	}
+
+	return factory()
+}
+
+// Match returns the first registered communicator, in priority order,
+// that claims machine.
+func (s *CommunicatorStore) Match(machine plugincore.Machine) (component.Communicator, error) {
+	for _, name := range s.names {
+		comm, err := s.Start(name)
+		if err != nil {
+			return nil, err
+		}
+
+		isMatch, err := comm.Match(machine)
+		if err != nil {
+			return nil, fmt.Errorf("communicator plugin %q failed to match: %w", name, err)
+		}
+		if isMatch {
+			return comm, nil
+		}
+	}
+
+	return nil, fmt.Errorf("no registered communicator plugin matched the machine")
+}
+
+// checkCommunicatorSpec validates a communicator plugin at
+// plugin-discovery time, failing fast if it cannot participate in
+// selection.
+func checkCommunicatorSpec(name string, factory func() (component.Communicator, error)) error {
+	comm, err := factory()
+	if err != nil {
+		return fmt.Errorf("communicator plugin %q failed to start: %w", name, err)
+	}
+
+	if comm.MatchFunc() == nil {
+		return fmt.Errorf("communicator plugin %q returned a nil MatchFunc", name)
+	}
+
+	return nil
+}
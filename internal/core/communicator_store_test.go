@@ -0,0 +1,148 @@
+package core
+
+import (
+	"testing"
+
+	"github.com/hashicorp/vagrant-plugin-sdk/component"
+	plugincore "github.com/hashicorp/vagrant-plugin-sdk/core"
+
+	"github.com/hashicorp/vagrant/builtin/myplugin"
+)
+
+// fakeCommunicator is a minimal component.Communicator used to exercise
+// CommunicatorStore's selection logic without depending on a real
+// plugin's Ready/Execute/transfer behavior.
+type fakeCommunicator struct {
+	matches bool
+}
+
+func (f *fakeCommunicator) MatchFunc() interface{} { return f.Match }
+func (f *fakeCommunicator) Match(machine plugincore.Machine) (bool, error) {
+	return f.matches, nil
+}
+
+func (f *fakeCommunicator) InitFunc() interface{}                { return f.Init }
+func (f *fakeCommunicator) Init(machine plugincore.Machine) error { return nil }
+
+func (f *fakeCommunicator) ReadyFunc() interface{} { return f.Ready }
+func (f *fakeCommunicator) Ready(machine plugincore.Machine) (bool, error) {
+	return true, nil
+}
+
+func (f *fakeCommunicator) WaitForReadyFunc() interface{} { return f.WaitForReady }
+func (f *fakeCommunicator) WaitForReady(machine plugincore.Machine, wait int) (bool, error) {
+	return true, nil
+}
+
+func (f *fakeCommunicator) DownloadFunc() interface{} { return f.Download }
+func (f *fakeCommunicator) Download(
+	machine plugincore.Machine,
+	entries []myplugin.TransferEntry,
+	options ...myplugin.CommunicatorOptions,
+) error {
+	return nil
+}
+
+func (f *fakeCommunicator) UploadFunc() interface{} { return f.Upload }
+func (f *fakeCommunicator) Upload(
+	machine plugincore.Machine,
+	entries []myplugin.TransferEntry,
+	options ...myplugin.CommunicatorOptions,
+) error {
+	return nil
+}
+
+func (f *fakeCommunicator) ExecuteFunc() interface{} { return f.Execute }
+func (f *fakeCommunicator) Execute(
+	machine plugincore.Machine,
+	command []string,
+	options ...myplugin.CommunicatorOptions,
+) (int32, error) {
+	return 0, nil
+}
+
+func (f *fakeCommunicator) PrivilegedExecuteFunc() interface{} { return f.PrivilegedExecute }
+func (f *fakeCommunicator) PrivilegedExecute(
+	machine plugincore.Machine,
+	command []string,
+	options ...myplugin.CommunicatorOptions,
+) (int32, error) {
+	return 0, nil
+}
+
+func (f *fakeCommunicator) TestFunc() interface{} { return f.Test }
+func (f *fakeCommunicator) Test(
+	machine plugincore.Machine,
+	command []string,
+	options ...myplugin.CommunicatorOptions,
+) (bool, error) {
+	return true, nil
+}
+
+func (f *fakeCommunicator) ResetFunc() interface{}                { return f.Reset }
+func (f *fakeCommunicator) Reset(machine plugincore.Machine) error { return nil }
+
+var _ component.Communicator = (*fakeCommunicator)(nil)
+
+func TestCommunicatorStoreMatchPriorityOrder(t *testing.T) {
+	winrm := &fakeCommunicator{matches: true}
+	dummy := &fakeCommunicator{matches: true}
+
+	communicators := CommunicatorFactories{
+		"winrm": func() (component.Communicator, error) { return winrm, nil },
+		"dummy": func() (component.Communicator, error) { return dummy, nil },
+	}
+
+	store, err := NewCommunicatorStore([]string{"winrm", "dummy"}, communicators)
+	if err != nil {
+		t.Fatalf("NewCommunicatorStore returned error: %v", err)
+	}
+
+	got, err := store.Match(nil)
+	if err != nil {
+		t.Fatalf("Match returned error: %v", err)
+	}
+	if got != component.Communicator(winrm) {
+		t.Fatal("Match did not return the higher-priority communicator when both matched")
+	}
+}
+
+func TestCommunicatorStoreMatchFallsThrough(t *testing.T) {
+	winrm := &fakeCommunicator{matches: false}
+	dummy := &fakeCommunicator{matches: true}
+
+	communicators := CommunicatorFactories{
+		"winrm": func() (component.Communicator, error) { return winrm, nil },
+		"dummy": func() (component.Communicator, error) { return dummy, nil },
+	}
+
+	store, err := NewCommunicatorStore([]string{"winrm", "dummy"}, communicators)
+	if err != nil {
+		t.Fatalf("NewCommunicatorStore returned error: %v", err)
+	}
+
+	got, err := store.Match(nil)
+	if err != nil {
+		t.Fatalf("Match returned error: %v", err)
+	}
+	if got != component.Communicator(dummy) {
+		t.Fatal("Match did not fall through to the next communicator when the first didn't match")
+	}
+}
+
+func TestCommunicatorStoreListPreservesOrder(t *testing.T) {
+	communicators := CommunicatorFactories{
+		"winrm": func() (component.Communicator, error) { return &fakeCommunicator{}, nil },
+		"dummy": func() (component.Communicator, error) { return &fakeCommunicator{}, nil },
+	}
+
+	store, err := NewCommunicatorStore([]string{"winrm", "dummy"}, communicators)
+	if err != nil {
+		t.Fatalf("NewCommunicatorStore returned error: %v", err)
+	}
+
+	names := store.List()
+	if len(names) != 2 || names[0] != "winrm" || names[1] != "dummy" {
+		t.Fatalf("List() = %v, want [winrm dummy]", names)
+	}
+}